@@ -1,8 +1,12 @@
 package bencode
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 )
 
@@ -20,15 +24,16 @@ func isDigit(c byte) bool {
 
 }
 
-func toBencodeString(in string) string {
-	return fmt.Sprintf("%v:%v", len(in), in)
-}
-
 type Value struct {
 	Kind Kind
 
 	String_ string
 
+	// Bytes holds the raw byte-string payload when the Value was built
+	// via NewBytes or decoded off the wire. It is nil for values built
+	// via NewString; GetBytes falls back to String_ in that case.
+	Bytes []byte
+
 	Number int
 
 	Array []*Value
@@ -40,6 +45,16 @@ func (value *Value) GetString() string {
 	return value.String_
 }
 
+// GetBytes returns the raw bytes of a String value, preserving
+// non-UTF8 data (SHA-1 pieces, arbitrary filenames) that round-tripped
+// through NewBytes or the decoder.
+func (value *Value) GetBytes() []byte {
+	if value.Bytes != nil {
+		return value.Bytes
+	}
+	return []byte(value.String_)
+}
+
 func (value *Value) GetNumber() int {
 	return value.Number
 }
@@ -84,25 +99,91 @@ func (value *Value) Prettify() string {
 	}
 }
 
+// Encode serializes value per the bencode spec, returning the result
+// as a string. It is a convenience wrapper around EncodeTo backed by a
+// bytes.Buffer; prefer EncodeTo when writing directly to a socket or
+// file, to avoid holding the whole encoding in memory twice.
 func (value *Value) Encode() string {
+	var buf bytes.Buffer
+	if _, err := value.EncodeTo(&buf); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// EncodeTo writes the bencode encoding of value to w, returning the
+// number of bytes written. Dictionary keys are sorted lexicographically
+// by their raw byte value, as BEP 3 requires, so that two calls over
+// equivalent trees always produce identical output (needed for
+// InfoHash and any other hash-of-encoding use).
+func (value *Value) EncodeTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	n, err := value.encodeTo(bw)
+	if err != nil {
+		return n, err
+	}
+	if err := bw.Flush(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+func (value *Value) encodeTo(w *bufio.Writer) (int64, error) {
+	var written int64
 	if value.Kind == String {
-		return toBencodeString(value.GetString())
+		b := value.GetBytes()
+		n, err := fmt.Fprintf(w, "%d:", len(b))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		m, err := w.Write(b)
+		written += int64(m)
+		return written, err
 	} else if value.Kind == Number {
-		return fmt.Sprintf("i%ve", value.GetNumber())
+		n, err := fmt.Fprintf(w, "i%ve", value.GetNumber())
+		return written + int64(n), err
 	} else if value.Kind == Array {
-		prettify := "l"
-		a := value.GetArray()
-		for i := 0; i < len(a); i++ {
-			prettify += a[i].Encode()
+		n, err := w.WriteString("l")
+		written += int64(n)
+		if err != nil {
+			return written, err
 		}
-		return prettify + "e"
+		for _, ele := range value.GetArray() {
+			m, err := ele.encodeTo(w)
+			written += m
+			if err != nil {
+				return written, err
+			}
+		}
+		n, err = w.WriteString("e")
+		return written + int64(n), err
 	} else if value.Kind == Object {
-		prettify := "d"
+		n, err := w.WriteString("d")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
 		o := value.GetObject()
-		for k, v := range o {
-			prettify += fmt.Sprintf("%v%v", toBencodeString(k), v.Encode())
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
 		}
-		return prettify + "e"
+		sort.Strings(keys)
+		for _, k := range keys {
+			m, err := fmt.Fprintf(w, "%d:%v", len(k), k)
+			written += int64(m)
+			if err != nil {
+				return written, err
+			}
+			mv, err := o[k].encodeTo(w)
+			written += mv
+			if err != nil {
+				return written, err
+			}
+		}
+		n, err = w.WriteString("e")
+		return written + int64(n), err
 	} else {
 		panic("impossible")
 	}
@@ -117,6 +198,13 @@ func NewString(in string) *Value {
 	return &Value{Kind: String, String_: in}
 }
 
+// NewBytes builds a String Value from raw bytes, for payloads such as
+// SHA-1 piece hashes or non-UTF8 filenames that must round-trip
+// losslessly.
+func NewBytes(in []byte) *Value {
+	return &Value{Kind: String, String_: string(in), Bytes: in}
+}
+
 func NewNumber(in int) *Value {
 	return &Value{Kind: Number, Number: in}
 }
@@ -226,7 +314,7 @@ func (ctx *Context) ParseString() (*Value, error) {
 	if string_, err := ctx.GetString(); err != nil {
 		return nil, err
 	} else {
-		return &Value{Kind: String, String_: string_}, nil
+		return &Value{Kind: String, String_: string_, Bytes: []byte(string_)}, nil
 	}
 }
 
@@ -0,0 +1,203 @@
+package bencode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// TokenKind identifies the shape of a Token produced by Decoder.Token.
+type TokenKind int
+
+const (
+	TokenDictStart TokenKind = iota
+	TokenListStart
+	TokenEnd
+	TokenInteger
+	TokenString
+)
+
+// Token is a single lexical element of a bencode stream.
+//
+// For TokenInteger, Integer holds the parsed value. For TokenString,
+// StringLen holds the declared length and StringReader is a reader
+// bounded to exactly that many bytes. It is valid only until the next
+// call to Token, which discards any of its bytes still unread.
+type Token struct {
+	Kind         TokenKind
+	Integer      int
+	StringLen    int
+	StringReader io.Reader
+}
+
+// Decoder reads a single pass of bencode tokens from an io.Reader
+// without buffering the whole input, so arbitrarily large payloads
+// (torrent metainfo, piece data) can be decoded in constant memory.
+type Decoder struct {
+	r       *bufio.Reader
+	pending io.Reader
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads one bencoded value from the stream and stores it in
+// the value pointed to by v, following the same rules as Unmarshal.
+func (d *Decoder) Decode(v interface{}) error {
+	value, err := d.decodeValue()
+	if err != nil {
+		return err
+	}
+	return unmarshalInto(value, v)
+}
+
+// Token returns the next token in the stream, or io.EOF once the
+// stream is exhausted.
+func (d *Decoder) Token() (Token, error) {
+	if d.pending != nil {
+		if _, err := io.Copy(io.Discard, d.pending); err != nil {
+			return Token{}, err
+		}
+		d.pending = nil
+	}
+
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return Token{}, err
+	}
+
+	if isDigit(c) {
+		return d.tokenString(c)
+	}
+
+	switch c {
+	case 'i':
+		return d.tokenInteger()
+	case 'l':
+		return Token{Kind: TokenListStart}, nil
+	case 'd':
+		return Token{Kind: TokenDictStart}, nil
+	case 'e':
+		return Token{Kind: TokenEnd}, nil
+	default:
+		return Token{}, fmt.Errorf("bencode: unexpected character %q", c)
+	}
+}
+
+func (d *Decoder) tokenInteger() (Token, error) {
+	digits, err := d.r.ReadString('e')
+	if err != nil {
+		return Token{}, err
+	}
+	digits = digits[:len(digits)-1]
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Token{}, errors.New("bencode: syntax error")
+	}
+	return Token{Kind: TokenInteger, Integer: int(n)}, nil
+}
+
+func (d *Decoder) tokenString(first byte) (Token, error) {
+	digits := []byte{first}
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return Token{}, err
+		}
+		if c == ':' {
+			break
+		}
+		if !isDigit(c) {
+			return Token{}, errors.New("bencode: syntax error")
+		}
+		digits = append(digits, c)
+	}
+
+	length, err := strconv.ParseInt(string(digits), 10, 64)
+	if err != nil {
+		return Token{}, errors.New("bencode: syntax error")
+	}
+
+	reader := io.LimitReader(d.r, length)
+	d.pending = reader
+	return Token{Kind: TokenString, StringLen: int(length), StringReader: reader}, nil
+}
+
+// decodeValue reads one full value from the token stream and
+// materializes it as a Value tree.
+func (d *Decoder) decodeValue() (*Value, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeFromToken(tok)
+}
+
+func (d *Decoder) decodeFromToken(tok Token) (*Value, error) {
+	switch tok.Kind {
+	case TokenInteger:
+		return NewNumber(tok.Integer), nil
+	case TokenString:
+		buf, err := io.ReadAll(tok.StringReader)
+		if err != nil {
+			return nil, err
+		}
+		return NewBytes(buf), nil
+	case TokenListStart:
+		value := &Value{Kind: Array}
+		for {
+			next, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			if next.Kind == TokenEnd {
+				break
+			}
+			ele, err := d.decodeFromToken(next)
+			if err != nil {
+				return nil, err
+			}
+			value.Array = append(value.Array, ele)
+		}
+		return value, nil
+	case TokenDictStart:
+		value := &Value{Kind: Object}
+		for {
+			keyTok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			if keyTok.Kind == TokenEnd {
+				break
+			}
+			if keyTok.Kind != TokenString {
+				return nil, errors.New("bencode: dictionary key must be a string")
+			}
+			keyBytes, err := io.ReadAll(keyTok.StringReader)
+			if err != nil {
+				return nil, err
+			}
+
+			attrTok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			attr, err := d.decodeFromToken(attrTok)
+			if err != nil {
+				return nil, err
+			}
+
+			if value.Object == nil {
+				value.Object = make(map[string]*Value)
+			}
+			value.Object[string(keyBytes)] = attr
+		}
+		return value, nil
+	default:
+		return nil, errors.New("bencode: unexpected end of value")
+	}
+}
@@ -0,0 +1,74 @@
+package bencode
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoderDecode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("d4:name4:test6:lengthi10ee"))
+
+	var out struct {
+		Name   string `bencode:"name"`
+		Length int    `bencode:"length"`
+	}
+	if err := dec.Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if out.Name != "test" || out.Length != 10 {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestDecoderTokenStream(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("d3:foo3:bar3:bazi42ee"))
+
+	want := []TokenKind{TokenDictStart, TokenString, TokenString, TokenString, TokenInteger, TokenEnd}
+	for i, kind := range want {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("Token %d: %v", i, err)
+		}
+		if tok.Kind != kind {
+			t.Fatalf("Token %d kind = %v, want %v", i, tok.Kind, kind)
+		}
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestDecoderTokenDrainsUnreadString verifies that calling Token again
+// without first reading a TokenString's StringReader to completion
+// still advances past it correctly.
+func TestDecoderTokenDrainsUnreadString(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("l5:hello3:fooe"))
+
+	tok, err := dec.Token()
+	if err != nil || tok.Kind != TokenListStart {
+		t.Fatalf("expected list start, got %v, %v", tok, err)
+	}
+
+	tok, err = dec.Token()
+	if err != nil || tok.Kind != TokenString || tok.StringLen != 5 {
+		t.Fatalf("expected 5-byte string token, got %v, %v", tok, err)
+	}
+	// Deliberately do not read tok.StringReader before calling Token
+	// again; the decoder must drain the remaining bytes itself.
+
+	tok, err = dec.Token()
+	if err != nil || tok.Kind != TokenString {
+		t.Fatalf("expected second string token, got %v, %v", tok, err)
+	}
+	data, err := io.ReadAll(tok.StringReader)
+	if err != nil || string(data) != "foo" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+
+	tok, err = dec.Token()
+	if err != nil || tok.Kind != TokenEnd {
+		t.Fatalf("expected end token, got %v, %v", tok, err)
+	}
+}
@@ -0,0 +1,32 @@
+package bencode
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+)
+
+// Encoder writes a sequence of bencode-encoded values to a stream,
+// pairing with Marshal so callers can pipe encoded data straight to a
+// socket or file without an intermediate []byte allocation.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the bencode encoding of v, following the same
+// reflection rules as Marshal.
+func (enc *Encoder) Encode(v interface{}) error {
+	value, err := marshalValue(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	if _, err := value.encodeTo(enc.w); err != nil {
+		return err
+	}
+	return enc.w.Flush()
+}
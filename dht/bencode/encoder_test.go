@@ -0,0 +1,74 @@
+package bencode
+
+import (
+	"bytes"
+	"testing"
+)
+
+type encoderFile struct {
+	Path   []string `bencode:"path"`
+	Length int      `bencode:"length"`
+}
+
+type encoderInfo struct {
+	Name  string        `bencode:"name"`
+	Files []encoderFile `bencode:"files"`
+}
+
+func TestEncoderMatchesMarshal(t *testing.T) {
+	in := encoderInfo{
+		Name:  "test",
+		Files: []encoderFile{{Path: []string{"a", "b"}, Length: 5}},
+	}
+
+	want, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	if buf.String() != string(want) {
+		t.Fatalf("Encoder output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncoderRoundTripsThroughDecode(t *testing.T) {
+	in := encoderInfo{
+		Name:  "test",
+		Files: []encoderFile{{Path: []string{"a", "b"}, Length: 5}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var out encoderInfo
+	if err := Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Name != in.Name || len(out.Files) != 1 || out.Files[0].Length != 5 || out.Files[0].Path[1] != "b" {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestEncodeToByteCountMatchesBuffer(t *testing.T) {
+	tree, err := Decode("d4:infod5:filesld6:lengthi5e4:pathl1:a1:beed6:lengthi9e4:pathl1:ceee4:name4:testee")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := tree.EncodeTo(&buf)
+	if err != nil {
+		t.Fatalf("EncodeTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("EncodeTo returned %d, buffer has %d bytes", n, buf.Len())
+	}
+}
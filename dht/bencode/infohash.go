@@ -0,0 +1,23 @@
+package bencode
+
+import (
+	"crypto/sha1"
+	"errors"
+)
+
+// InfoHash computes the SHA-1 digest of the canonical encoding of the
+// "info" sub-dictionary of v, which is the torrent identifier used in
+// tracker requests, magnet links and peer handshakes. It returns an
+// error rather than panicking if v is not a dictionary or has no
+// "info" key, since v routinely comes from untrusted or malformed
+// .torrent/DHT data.
+func InfoHash(v *Value) ([20]byte, error) {
+	if v.Kind != Object {
+		return [20]byte{}, errors.New("bencode: InfoHash requires a dictionary")
+	}
+	info, ok := v.GetObject()["info"]
+	if !ok {
+		return [20]byte{}, errors.New("bencode: InfoHash: missing \"info\" key")
+	}
+	return sha1.Sum([]byte(info.Encode())), nil
+}
@@ -0,0 +1,39 @@
+package bencode
+
+import "testing"
+
+func TestInfoHash(t *testing.T) {
+	v, err := Decode("d4:infod4:name4:teste6:lengthi10ee")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	hash, err := InfoHash(v)
+	if err != nil {
+		t.Fatalf("InfoHash: %v", err)
+	}
+
+	again, err := InfoHash(v)
+	if err != nil || again != hash {
+		t.Fatalf("InfoHash is not deterministic: %v, %v vs %v", err, hash, again)
+	}
+}
+
+func TestInfoHashMissingInfo(t *testing.T) {
+	v, err := Decode("d4:spam3:fooe")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if _, err := InfoHash(v); err == nil {
+		t.Fatal("expected an error for a dictionary with no \"info\" key")
+	}
+}
+
+func TestInfoHashNotADictionary(t *testing.T) {
+	v := NewNumber(42)
+
+	if _, err := InfoHash(v); err == nil {
+		t.Fatal("expected an error for a non-dictionary value")
+	}
+}
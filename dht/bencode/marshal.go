@@ -0,0 +1,287 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Marshal returns the bencode encoding of v.
+//
+// Marshal traverses v with reflect. Struct fields become dictionary
+// entries keyed by the field name, unless overridden with a
+// `bencode:"name"` tag; a tag of "-" skips the field entirely, and a
+// trailing ",omitempty" option skips the field when it holds its zero
+// value. Ints of any width, strings, []byte, slices, arrays and maps
+// with string keys are all supported, with pointers and interfaces
+// indirected along the way.
+func Marshal(v interface{}) ([]byte, error) {
+	value, err := marshalValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value.Encode()), nil
+}
+
+// Unmarshal parses bencoded data and stores the result in the value
+// pointed to by v. Decoding into a struct fills its exported fields,
+// matched by tag name or, for untagged fields, by field name, and
+// silently skips unknown dictionary keys; decoding
+// into a map[string]interface{} (or interface{}) preserves everything.
+func Unmarshal(data []byte, v interface{}) error {
+	value, err := Decode(string(data))
+	if err != nil {
+		return err
+	}
+	return unmarshalInto(value, v)
+}
+
+func unmarshalInto(value *Value, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("bencode: Unmarshal requires a non-nil pointer")
+	}
+	return unmarshalValue(value, rv.Elem())
+}
+
+func marshalValue(rv reflect.Value) (*Value, error) {
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("bencode: cannot encode nil value")
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewNumber(int(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NewNumber(int(rv.Uint())), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if rv.Kind() == reflect.Slice {
+				return NewBytes(rv.Bytes()), nil
+			}
+			buf := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(buf), rv)
+			return NewBytes(buf), nil
+		}
+		array := make([]*Value, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			ele, err := marshalValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			array[i] = ele
+		}
+		return &Value{Kind: Array, Array: array}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, errors.New("bencode: map key must be a string")
+		}
+		object := make(map[string]*Value, rv.Len())
+		for _, key := range rv.MapKeys() {
+			ele, err := marshalValue(rv.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			object[key.String()] = ele
+		}
+		return &Value{Kind: Object, Object: object}, nil
+	case reflect.Struct:
+		object := make(map[string]*Value)
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, omitempty, skip := fieldTag(field)
+			if skip {
+				continue
+			}
+			fv := rv.Field(i)
+			if omitempty && isEmptyValue(fv) {
+				continue
+			}
+			ele, err := marshalValue(fv)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = ele
+		}
+		return &Value{Kind: Object, Object: object}, nil
+	default:
+		return nil, fmt.Errorf("bencode: unsupported kind %v", rv.Kind())
+	}
+}
+
+func unmarshalValue(value *Value, rv reflect.Value) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(value, rv.Elem())
+	}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(genericValue(value)))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Kind != Number {
+			return errors.New("bencode: expected integer")
+		}
+		rv.SetInt(int64(value.Number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Kind != Number {
+			return errors.New("bencode: expected integer")
+		}
+		rv.SetUint(uint64(value.Number))
+	case reflect.String:
+		if value.Kind != String {
+			return errors.New("bencode: expected string")
+		}
+		rv.SetString(value.GetString())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if value.Kind != String {
+				return errors.New("bencode: expected byte string")
+			}
+			rv.SetBytes(append([]byte(nil), value.GetBytes()...))
+			return nil
+		}
+		if value.Kind != Array {
+			return errors.New("bencode: expected list")
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(value.Array), len(value.Array))
+		for i, ele := range value.Array {
+			if err := unmarshalValue(ele, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if value.Kind != String {
+				return errors.New("bencode: expected byte string")
+			}
+			reflect.Copy(rv, reflect.ValueOf(value.GetBytes()))
+			return nil
+		}
+		if value.Kind != Array {
+			return errors.New("bencode: expected list")
+		}
+		for i := 0; i < rv.Len() && i < len(value.Array); i++ {
+			if err := unmarshalValue(value.Array[i], rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if value.Kind != Object {
+			return errors.New("bencode: expected dictionary")
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return errors.New("bencode: map key must be a string")
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), len(value.Object))
+		for k, v := range value.Object {
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := unmarshalValue(v, ev); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), ev)
+		}
+		rv.Set(m)
+	case reflect.Struct:
+		if value.Kind != Object {
+			return errors.New("bencode: expected dictionary")
+		}
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, _, skip := fieldTag(field)
+			if skip {
+				continue
+			}
+			attr, ok := value.Object[name]
+			if !ok {
+				continue
+			}
+			if err := unmarshalValue(attr, rv.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("bencode: unsupported kind %v", rv.Kind())
+	}
+	return nil
+}
+
+// genericValue converts a Value into plain Go values (string, int,
+// []interface{}, map[string]interface{}) for decoding into interface{}.
+func genericValue(value *Value) interface{} {
+	switch value.Kind {
+	case String:
+		return value.GetString()
+	case Number:
+		return value.GetNumber()
+	case Array:
+		out := make([]interface{}, len(value.Array))
+		for i, ele := range value.Array {
+			out[i] = genericValue(ele)
+		}
+		return out
+	case Object:
+		out := make(map[string]interface{}, len(value.Object))
+		for k, ele := range value.Object {
+			out[k] = genericValue(ele)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// fieldTag parses the `bencode:"name,omitempty"` tag on f.
+func fieldTag(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("bencode")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
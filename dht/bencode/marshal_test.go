@@ -0,0 +1,95 @@
+package bencode
+
+import (
+	"bytes"
+	"testing"
+)
+
+type marshalFile struct {
+	Path   []string `bencode:"path"`
+	Length int      `bencode:"length"`
+}
+
+type marshalInfo struct {
+	Name    string        `bencode:"name"`
+	Files   []marshalFile `bencode:"files,omitempty"`
+	Private int           `bencode:"private,omitempty"`
+	Pieces  []byte        `bencode:"pieces"`
+	secret  string
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	in := marshalInfo{
+		Name:   "test",
+		Files:  []marshalFile{{Path: []string{"a", "b"}, Length: 5}},
+		Pieces: []byte{1, 2, 3},
+		secret: "unexported fields must be ignored",
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out marshalInfo
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if out.Name != in.Name {
+		t.Fatalf("Name = %q, want %q", out.Name, in.Name)
+	}
+	if len(out.Files) != 1 || out.Files[0].Length != 5 || out.Files[0].Path[1] != "b" {
+		t.Fatalf("Files = %+v", out.Files)
+	}
+	if !bytes.Equal(out.Pieces, in.Pieces) {
+		t.Fatalf("Pieces = %v, want %v", out.Pieces, in.Pieces)
+	}
+}
+
+func TestMarshalOmitempty(t *testing.T) {
+	data, err := Marshal(marshalInfo{Name: "test", Pieces: []byte{}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	value, err := Decode(string(data))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if _, ok := value.GetObject()["files"]; ok {
+		t.Fatal("omitempty field \"files\" should have been skipped")
+	}
+	if _, ok := value.GetObject()["private"]; ok {
+		t.Fatal("omitempty field \"private\" should have been skipped")
+	}
+}
+
+func TestUnmarshalSkipsUnknownKeys(t *testing.T) {
+	data := []byte("d4:name4:test7:unknowni1ee")
+
+	var out marshalInfo
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Name != "test" {
+		t.Fatalf("Name = %q, want %q", out.Name, "test")
+	}
+}
+
+func TestUnmarshalIntoMap(t *testing.T) {
+	data := []byte("d4:name4:test5:filesl4:spameee")
+
+	var m map[string]interface{}
+	if err := Unmarshal(data, &m); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if m["name"] != "test" {
+		t.Fatalf("name = %v, want %q", m["name"], "test")
+	}
+	files, ok := m["files"].([]interface{})
+	if !ok || len(files) != 1 || files[0] != "spam" {
+		t.Fatalf("files = %v", m["files"])
+	}
+}
@@ -0,0 +1,179 @@
+package bencode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathStepKind int
+
+const (
+	stepKey pathStepKind = iota
+	stepIndex
+	stepWildcard
+	stepRecursiveKey
+)
+
+type pathStep struct {
+	kind  pathStepKind
+	key   string
+	index int
+}
+
+// Path is a compiled Value path; see CompilePath.
+type Path struct {
+	steps []pathStep
+}
+
+// CompilePath parses a path expression once so it can be evaluated
+// against many trees with Value.Query / Value.Get.
+//
+// Supported syntax: a leading "/" for the root (optional), ".key" for
+// dictionary access, "[n]" for list indexing, "[*]" for a list
+// wildcard, and "..key" for recursive descent, e.g.
+// "info.files[*].path[0]" or "..piece length".
+func CompilePath(path string) (*Path, error) {
+	p := strings.TrimPrefix(path, "/")
+
+	var steps []pathStep
+	for len(p) > 0 {
+		switch {
+		case strings.HasPrefix(p, ".."):
+			key, rest, err := readKey(p[2:])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{kind: stepRecursiveKey, key: key})
+			p = rest
+		case strings.HasPrefix(p, "."):
+			key, rest, err := readKey(p[1:])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{kind: stepKey, key: key})
+			p = rest
+		case strings.HasPrefix(p, "["):
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, errors.New("bencode: unterminated [ in path")
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			if inner == "*" {
+				steps = append(steps, pathStep{kind: stepWildcard})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("bencode: invalid index %q in path", inner)
+			}
+			steps = append(steps, pathStep{kind: stepIndex, index: idx})
+		default:
+			// A bare key with no leading "." is read as a dictionary
+			// step, e.g. the first step of info.Query("files[*].path[0]");
+			// it is accepted after any step, not only the first.
+			key, rest, err := readKey(p)
+			if err != nil {
+				return nil, fmt.Errorf("bencode: unexpected character %q in path", p[0])
+			}
+			steps = append(steps, pathStep{kind: stepKey, key: key})
+			p = rest
+		}
+	}
+	return &Path{steps: steps}, nil
+}
+
+// readKey reads a bare key up to the next '.' or '[', as used after a
+// "." or ".." path separator.
+func readKey(p string) (key, rest string, err error) {
+	i := 0
+	for i < len(p) && p[i] != '.' && p[i] != '[' {
+		i++
+	}
+	if i == 0 {
+		return "", "", errors.New("bencode: expected key in path")
+	}
+	return p[:i], p[i:], nil
+}
+
+// Query evaluates path against value and returns every matching node.
+func (value *Value) Query(path string) ([]*Value, error) {
+	compiled, err := CompilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Evaluate(value), nil
+}
+
+// Get evaluates path against value and returns its first match, or an
+// error if nothing matched.
+func (value *Value) Get(path string) (*Value, error) {
+	results, err := value.Query(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("bencode: no match for path %q", path)
+	}
+	return results[0], nil
+}
+
+// Evaluate applies the compiled path to value.
+func (p *Path) Evaluate(value *Value) []*Value {
+	current := []*Value{value}
+	for _, step := range p.steps {
+		var next []*Value
+		for _, v := range current {
+			next = append(next, evalStep(step, v)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func evalStep(step pathStep, value *Value) []*Value {
+	switch step.kind {
+	case stepKey:
+		if value.Kind != Object {
+			return nil
+		}
+		if child, ok := value.Object[step.key]; ok {
+			return []*Value{child}
+		}
+		return nil
+	case stepIndex:
+		if value.Kind != Array || step.index < 0 || step.index >= len(value.Array) {
+			return nil
+		}
+		return []*Value{value.Array[step.index]}
+	case stepWildcard:
+		if value.Kind != Array {
+			return nil
+		}
+		return append([]*Value(nil), value.Array...)
+	case stepRecursiveKey:
+		var matches []*Value
+		var walk func(v *Value)
+		walk = func(v *Value) {
+			switch v.Kind {
+			case Object:
+				if child, ok := v.Object[step.key]; ok {
+					matches = append(matches, child)
+				}
+				for _, child := range v.Object {
+					walk(child)
+				}
+			case Array:
+				for _, child := range v.Array {
+					walk(child)
+				}
+			}
+		}
+		walk(value)
+		return matches
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package bencode
+
+import "testing"
+
+func torrentFixture(t *testing.T) *Value {
+	t.Helper()
+	v, err := Decode("d4:infod5:filesld6:lengthi5e4:pathl1:a1:beed6:lengthi9e4:pathl1:ceee4:name4:testee")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return v
+}
+
+func TestQueryDictAndIndex(t *testing.T) {
+	v := torrentFixture(t)
+
+	results, err := v.Query("/info.name")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) != 1 || results[0].GetString() != "test" {
+		t.Fatalf("got %+v", results)
+	}
+
+	result, err := v.Get("info.files[0].length")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if result.GetNumber() != 5 {
+		t.Fatalf("length = %d, want 5", result.GetNumber())
+	}
+}
+
+func TestQueryWildcard(t *testing.T) {
+	v := torrentFixture(t)
+
+	info, err := v.Get("info")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	results, err := info.Query("files[*].path[0]")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) != 2 || results[0].GetString() != "a" || results[1].GetString() != "c" {
+		t.Fatalf("got %+v", results)
+	}
+}
+
+func TestQueryRecursiveDescent(t *testing.T) {
+	v := torrentFixture(t)
+
+	results, err := v.Query("..length")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d matches, want 2: %+v", len(results), results)
+	}
+}
+
+func TestGetNoMatch(t *testing.T) {
+	v := torrentFixture(t)
+
+	if _, err := v.Get("info.missing"); err == nil {
+		t.Fatal("expected an error for a path with no matches")
+	}
+}
+
+func TestCompilePathInvalid(t *testing.T) {
+	if _, err := CompilePath("info[bad]"); err == nil {
+		t.Fatal("expected an error for a non-numeric, non-wildcard index")
+	}
+	if _, err := CompilePath("info[0"); err == nil {
+		t.Fatal("expected an error for an unterminated [")
+	}
+}